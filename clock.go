@@ -0,0 +1,64 @@
+package performance
+
+import "time"
+
+// Clock abstracts away real time so a Runner can be driven deterministically
+// in tests. The default, used unless WithClock overrides it, is backed by
+// the time package.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTicker returns a Ticker that fires every d.
+	NewTicker(d time.Duration) Ticker
+
+	// NewTimer returns a Timer that fires once, after d.
+	NewTimer(d time.Duration) Timer
+
+	// Sleep blocks for d. A Runner uses it both to space requests within a
+	// tick and for retry backoff between attempts.
+	Sleep(d time.Duration)
+}
+
+// Ticker abstracts time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer abstracts time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// realClock is the default Clock, backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+func (r realTimer) Stop() bool          { return r.t.Stop() }
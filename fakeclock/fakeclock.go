@@ -0,0 +1,177 @@
+// Package fakeclock provides a deterministic implementation of
+// performance.Clock for testing the Runner itself without waiting on real
+// time.
+package fakeclock
+
+import (
+	"sync"
+	"time"
+
+	"performance"
+)
+
+// FakeClock is a performance.Clock that only moves forward when Advance is
+// called, letting tests step a Runner through ramp-up, tick alignment, and
+// timer expiry synchronously.
+type FakeClock struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	now  time.Time
+
+	tickers []*fakeTicker
+	timers  []*fakeTimer
+}
+
+// New returns a FakeClock starting at start.
+func New(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Now implements performance.Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTicker implements performance.Clock.
+func (c *FakeClock) NewTicker(d time.Duration) performance.Ticker {
+	c.mu.Lock()
+
+	t := &fakeTicker{period: d, next: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+
+	c.mu.Unlock()
+	c.cond.Broadcast()
+
+	return t
+}
+
+// NewTimer implements performance.Clock.
+func (c *FakeClock) NewTimer(d time.Duration) performance.Timer {
+	c.mu.Lock()
+
+	t := &fakeTimer{fireAt: c.now.Add(d), ch: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+
+	c.mu.Unlock()
+	c.cond.Broadcast()
+
+	return t
+}
+
+// WaitForWatchers blocks until at least n tickers and timers combined have
+// been registered via NewTicker/NewTimer. A caller that advances the clock
+// from a separate goroutine than the one driving the Runner (the common
+// case in tests) must call this first: otherwise an Advance racing ahead of
+// Run's NewTicker/NewTimer calls computes fire times against an
+// already-advanced now and never fires within the test's advance budget.
+func (c *FakeClock) WaitForWatchers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.tickers)+len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// Sleep blocks until the clock has been advanced by at least d.
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for c.now.Before(target) {
+		c.cond.Wait()
+	}
+}
+
+// Advance moves the clock forward by d, firing any ticker or timer whose
+// deadline has been reached and waking anything blocked in Sleep.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+
+	c.now = c.now.Add(d)
+
+	for _, t := range c.tickers {
+		t.fire(c.now)
+	}
+
+	for _, t := range c.timers {
+		t.fire(c.now)
+	}
+
+	c.mu.Unlock()
+	c.cond.Broadcast()
+}
+
+type fakeTicker struct {
+	mu      sync.Mutex
+	ch      chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) fire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for !t.stopped && !t.next.After(now) {
+		select {
+		case t.ch <- now:
+		default:
+		}
+
+		t.next = t.next.Add(t.period)
+	}
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+}
+
+type fakeTimer struct {
+	mu      sync.Mutex
+	ch      chan time.Time
+	fireAt  time.Time
+	fired   bool
+	stopped bool
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stopped || t.fired || t.fireAt.After(now) {
+		return
+	}
+
+	select {
+	case t.ch <- now:
+	default:
+	}
+
+	t.fired = true
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	wasActive := !t.fired && !t.stopped
+	t.stopped = true
+
+	return wasActive
+}
@@ -0,0 +1,222 @@
+package performance
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// histogram is a fixed-memory, HDR-style latency histogram. Values are
+// bucketed by order of magnitude (the "bucket") and linearly within that
+// order of magnitude (the "sub-bucket"), which bounds both the memory
+// footprint and the relative error of any reported value regardless of how
+// many observations are recorded. Writes are sharded so that concurrent
+// goroutines hitting the same bucket don't contend on a single counter.
+type histogram struct {
+	lowest  int64
+	highest int64
+
+	subBits    uint
+	subCount   int64
+	numBuckets int
+
+	shards []histogramShard
+}
+
+// histogramShard holds one shard's worth of bucket counters. Each counter is
+// updated with atomic.AddUint64 so a shard can be written from multiple
+// goroutines without a lock.
+type histogramShard struct {
+	counts [][]uint64
+}
+
+// newHistogram builds a histogram covering [lowest, highest] (in
+// nanoseconds) with the given number of significant decimal figures of
+// precision, split across shardCount independent shards.
+func newHistogram(lowest, highest int64, significantFigures, shardCount int) *histogram {
+	if shardCount < 1 {
+		shardCount = 1
+	}
+
+	subBits := uint(math.Ceil(math.Log2(math.Pow(10, float64(significantFigures)))))
+
+	h := &histogram{
+		lowest:     lowest,
+		highest:    highest,
+		subBits:    subBits,
+		subCount:   int64(1) << subBits,
+		numBuckets: bucketIndex(highest) + 2,
+		shards:     make([]histogramShard, shardCount),
+	}
+
+	for i := range h.shards {
+		counts := make([][]uint64, h.numBuckets)
+		for k := range counts {
+			counts[k] = make([]uint64, h.subCount)
+		}
+
+		h.shards[i].counts = counts
+	}
+
+	return h
+}
+
+// bucketIndex returns k = floor(log2(v)), the order of magnitude v falls
+// into.
+func bucketIndex(v int64) int {
+	if v < 2 {
+		return 0
+	}
+
+	return bits.Len64(uint64(v)) - 1
+}
+
+// subBucketIndex returns the linear offset of v within bucket k.
+func (h *histogram) subBucketIndex(v int64, k int) int64 {
+	if k < int(h.subBits) {
+		return v
+	}
+
+	return (v - (int64(1) << uint(k))) >> uint(k-int(h.subBits))
+}
+
+// valueOf reconstructs the representative value of bucket (k, sub), i.e. the
+// inverse of subBucketIndex.
+func (h *histogram) valueOf(k int, sub int64) int64 {
+	if k < int(h.subBits) {
+		return sub
+	}
+
+	return (int64(1) << uint(k)) + (sub << uint(k-int(h.subBits)))
+}
+
+// record adds an observation to the shard selected by shardHint (typically
+// the sequence number of the goroutine doing the recording, so that
+// concurrent writers spread across shards without needing a real per-P id).
+func (h *histogram) record(shardHint int, value time.Duration) {
+	v := int64(value)
+
+	if v < h.lowest {
+		v = h.lowest
+	}
+
+	if v > h.highest {
+		v = h.highest
+	}
+
+	k := bucketIndex(v)
+	sub := h.subBucketIndex(v, k)
+
+	shard := shardHint % len(h.shards)
+	if shard < 0 {
+		shard += len(h.shards)
+	}
+
+	atomic.AddUint64(&h.shards[shard].counts[k][sub], 1)
+}
+
+// histogramStats holds the aggregate statistics produced by merging a
+// histogram's shards.
+type histogramStats struct {
+	Count  uint64
+	Min    int64
+	Max    int64
+	Mean   float64
+	StdDev float64
+}
+
+// merge sums all shards' counters and walks the result once to compute
+// count, min, max, mean and standard deviation, plus the value at each
+// requested percentile (0-100).
+func (h *histogram) merge(percentiles []float64) (histogramStats, map[float64]int64) {
+	merged := make([][]uint64, h.numBuckets)
+	for k := range merged {
+		merged[k] = make([]uint64, h.subCount)
+
+		for _, shard := range h.shards {
+			for sub, n := range shard.counts[k] {
+				if n != 0 {
+					merged[k][sub] += n
+				}
+			}
+		}
+	}
+
+	stats := histogramStats{}
+
+	var total uint64
+	var sum, sumSq float64
+
+	for k := 0; k < h.numBuckets; k++ {
+		for sub := int64(0); sub < h.subCount; sub++ {
+			n := merged[k][sub]
+			if n == 0 {
+				continue
+			}
+
+			value := h.valueOf(k, sub)
+
+			if total == 0 {
+				stats.Min = value
+			}
+
+			stats.Max = value
+
+			total += n
+			sum += float64(value) * float64(n)
+			sumSq += float64(value) * float64(value) * float64(n)
+		}
+	}
+
+	stats.Count = total
+
+	if total > 0 {
+		stats.Mean = sum / float64(total)
+		variance := sumSq/float64(total) - stats.Mean*stats.Mean
+
+		if variance > 0 {
+			stats.StdDev = math.Sqrt(variance)
+		}
+	}
+
+	results := make(map[float64]int64, len(percentiles))
+
+	for _, p := range percentiles {
+		results[p] = h.valueAtPercentile(merged, total, p)
+	}
+
+	return stats, results
+}
+
+// valueAtPercentile walks the merged buckets in ascending order and returns
+// the value at which the given percentile (0-100) of observations has been
+// seen.
+func (h *histogram) valueAtPercentile(merged [][]uint64, total uint64, percentile float64) int64 {
+	if total == 0 {
+		return 0
+	}
+
+	target := uint64(math.Ceil(float64(total) * percentile / 100))
+	if target == 0 {
+		target = 1
+	}
+
+	var seen uint64
+
+	for k := 0; k < h.numBuckets; k++ {
+		for sub := int64(0); sub < h.subCount; sub++ {
+			n := merged[k][sub]
+			if n == 0 {
+				continue
+			}
+
+			seen += n
+			if seen >= target {
+				return h.valueOf(k, sub)
+			}
+		}
+	}
+
+	return h.valueOf(h.numBuckets-1, h.subCount-1)
+}
@@ -0,0 +1,104 @@
+package performance
+
+// OutputFormat controls how a Runner renders its final Summary.
+type OutputFormat uint8
+
+const (
+	// OutputFormatBoxed prints a human-friendly boxed report. This is the
+	// default.
+	OutputFormatBoxed OutputFormat = iota
+
+	// OutputFormatBenchmark prints the standard `go test -bench` text
+	// format, so runs can be compared with benchstat.
+	OutputFormatBenchmark
+)
+
+// RunnerOption configures optional Runner behavior.
+type RunnerOption func(*Runner)
+
+// WithSinks attaches sinks that receive live metrics as the run progresses,
+// in addition to the Summary returned once it finishes.
+func WithSinks(sinks ...Sink) RunnerOption {
+	return func(r *Runner) {
+		r.sinks = append(r.sinks, sinks...)
+	}
+}
+
+// WithOutputFormat sets the format used to render the final Summary.
+// Defaults to OutputFormatBoxed.
+func WithOutputFormat(format OutputFormat) RunnerOption {
+	return func(r *Runner) {
+		r.outputFormat = format
+	}
+}
+
+// WithName sets the name used to label Summary.BenchmarkFormat output when
+// OutputFormatBenchmark is selected. Defaults to "LoadTest".
+func WithName(name string) RunnerOption {
+	return func(r *Runner) {
+		r.name = name
+	}
+}
+
+// WithClock overrides the Clock a Runner uses for ticking, timing, and
+// sleeping between requests. Defaults to a Clock backed by the time
+// package; tests can pass a fakeclock.FakeClock to drive a Runner
+// deterministically instead of waiting on real time.
+func WithClock(clock Clock) RunnerOption {
+	return func(r *Runner) {
+		r.clock = clock
+	}
+}
+
+// WithCPUProfile enables CPU profiling for the run, writing pprof output to
+// path.
+func WithCPUProfile(path string) RunnerOption {
+	return func(r *Runner) {
+		r.cpuProfilePath = path
+	}
+}
+
+// WithHeapProfile writes a heap snapshot to path once the run completes.
+func WithHeapProfile(path string) RunnerOption {
+	return func(r *Runner) {
+		r.heapProfilePath = path
+	}
+}
+
+// WithTraceFile wraps the run in a runtime/trace execution trace, written
+// to path.
+func WithTraceFile(path string) RunnerOption {
+	return func(r *Runner) {
+		r.traceFilePath = path
+	}
+}
+
+// WithWeightedRandomScenarios switches scenario selection from the default
+// deterministic, interleaved round-robin to a weighted-random pick seeded
+// with seed, so a multi-scenario run can still be reproduced exactly.
+func WithWeightedRandomScenarios(seed int64) RunnerOption {
+	return func(r *Runner) {
+		r.weightedRandomSeed = &seed
+	}
+}
+
+// WithRetry enables per-call retries under policy: a call whose error is
+// retryable is retried, with exponential backoff and jitter between
+// attempts, up to policy.MaxAttempts. Without this option every call is
+// attempted exactly once.
+func WithRetry(policy RetryPolicy) RunnerOption {
+	return func(r *Runner) {
+		r.retry = policy
+	}
+}
+
+// WithRuntimeMetrics samples the named histogram-valued runtime/metrics
+// (see runtime/metrics.All, e.g. "/gc/pauses:seconds" or
+// "/sched/latencies:seconds") and includes their distribution over the run
+// in the resulting Summary, so p99 latency spikes can be correlated with GC
+// or scheduler behavior.
+func WithRuntimeMetrics(names []string) RunnerOption {
+	return func(r *Runner) {
+		r.runtimeMetricNames = names
+	}
+}
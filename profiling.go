@@ -0,0 +1,68 @@
+package performance
+
+import (
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// startProfiling begins any pprof CPU profile and execution trace the
+// Runner was configured with via WithCPUProfile / WithTraceFile. Pair it
+// with a call to stopProfiling once the run completes, which also writes
+// the heap snapshot if WithHeapProfile was configured.
+func (p *Runner) startProfiling() {
+	if p.cpuProfilePath != "" {
+		f, err := os.Create(p.cpuProfilePath)
+		if err != nil {
+			p.debug(fmt.Sprintf("cpu profile error: %s", err))
+		} else if err := pprof.StartCPUProfile(f); err != nil {
+			p.debug(fmt.Sprintf("cpu profile error: %s", err))
+			f.Close()
+		} else {
+			p.cpuProfileFile = f
+		}
+	}
+
+	if p.traceFilePath != "" {
+		f, err := os.Create(p.traceFilePath)
+		if err != nil {
+			p.debug(fmt.Sprintf("trace error: %s", err))
+		} else if err := trace.Start(f); err != nil {
+			p.debug(fmt.Sprintf("trace error: %s", err))
+			f.Close()
+		} else {
+			p.traceFile = f
+		}
+	}
+}
+
+// stopProfiling ends any active CPU profile and execution trace, and writes
+// a heap snapshot if WithHeapProfile was configured.
+func (p *Runner) stopProfiling() {
+	if p.cpuProfileFile != nil {
+		pprof.StopCPUProfile()
+		p.cpuProfileFile.Close()
+	}
+
+	if p.traceFile != nil {
+		trace.Stop()
+		p.traceFile.Close()
+	}
+
+	if p.heapProfilePath == "" {
+		return
+	}
+
+	f, err := os.Create(p.heapProfilePath)
+	if err != nil {
+		p.debug(fmt.Sprintf("heap profile error: %s", err))
+
+		return
+	}
+	defer f.Close()
+
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		p.debug(fmt.Sprintf("heap profile error: %s", err))
+	}
+}
@@ -0,0 +1,88 @@
+package performance
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how a Runner retries a Scenario call that fails
+// with a retryable error, rather than counting every transient failure
+// (rate limits, leader elections) as a hard one.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a call is attempted,
+	// including the first. A value of 0 or 1 disables retries.
+	MaxAttempts uint8
+
+	// InitialDelay is the backoff before the second attempt.
+	InitialDelay time.Duration
+
+	// MaxDelay caps the backoff applied before any attempt. The cap applies
+	// before jitter, so a jittered delay can exceed MaxDelay by up to the
+	// Jitter fraction.
+	MaxDelay time.Duration
+
+	// Multiplier scales the delay after each failed attempt. Defaults to 2
+	// when zero.
+	Multiplier float64
+
+	// Jitter randomizes the delay by up to this fraction (0..1) in either
+	// direction, so retries from many goroutines don't line back up into a
+	// thundering herd.
+	Jitter float64
+
+	// RetryableFn decides whether an error is worth retrying. A nil
+	// RetryableFn retries every non-nil error.
+	RetryableFn func(error) bool
+}
+
+// maxAttempts normalizes MaxAttempts to at least 1.
+func (p RetryPolicy) maxAttempts() uint8 {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// retryable reports whether gErr should be retried under this policy.
+func (p RetryPolicy) retryable(gErr error) bool {
+	if gErr == nil {
+		return false
+	}
+
+	if p.RetryableFn == nil {
+		return true
+	}
+
+	return p.RetryableFn(gErr)
+}
+
+// backoff returns the delay to sleep before attempt (1-indexed: the delay
+// before the second attempt is backoff(1)), with jitter applied.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialDelay) * math.Pow(p.multiplier(), float64(attempt-1))
+
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delta := delay * p.Jitter
+		delay += (rand.Float64()*2 - 1) * delta
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	return time.Duration(delay)
+}
+
+func (p RetryPolicy) multiplier() float64 {
+	if p.Multiplier <= 0 {
+		return 2
+	}
+
+	return p.Multiplier
+}
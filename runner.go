@@ -5,25 +5,57 @@ package performance
 import (
 	"context"
 	"fmt"
-	"sort"
+	"os"
+	"runtime"
+	"runtime/metrics"
 	"sync"
 	"time"
 
 	"github.com/Avalanche-io/counter"
-	"github.com/oklog/ulid/v2"
+)
+
+// defaultPercentiles are the percentiles reported in every Summary.
+var defaultPercentiles = []float64{50, 75, 90, 95, 99, 99.9, 99.99}
+
+const (
+	// histogramLowest and histogramHighest bound the latencies the runner's
+	// histogram can represent. Observations outside this range are clamped,
+	// which only matters for pathologically fast or slow callbacks.
+	histogramLowest  = int64(time.Microsecond)
+	histogramHighest = int64(60 * time.Second)
+
+	// histogramSignificantFigures trades memory for precision: 2 figures
+	// keeps the histogram small while keeping relative error under 1%.
+	histogramSignificantFigures = 2
 )
 
 // Runner is a performance test runner.
 type Runner struct {
-	callbackFn     CallbackFn
+	scenarios      []Scenario
 	duration       time.Duration
 	callsPerSecond uint16
 	verbose        bool
+	name           string
+	outputFormat   OutputFormat
+	clock          Clock
+
+	picker             scenarioPicker
+	weightedRandomSeed *int64
+	scenarioStates     map[string]*requestStats
 
-	errors    sync.Map
-	latencies sync.Map
-	okCount   *counter.UnsignedCounter
-	errCount  *counter.UnsignedCounter
+	cpuProfilePath  string
+	heapProfilePath string
+	traceFilePath   string
+	cpuProfileFile  *os.File
+	traceFile       *os.File
+
+	runtimeMetricNames []string
+	runtimeMetricsBase map[string]*metrics.Float64Histogram
+	memStatsBase       runtime.MemStats
+
+	retry  RetryPolicy
+	global *requestStats
+	sinks  []Sink
 
 	startedAt time.Time
 	summary   *Summary
@@ -33,23 +65,52 @@ type Runner struct {
 // performance is subject to be evaluated.
 type CallbackFn func() error
 
-// NewRunner creates a new performance test runner.
+// NewRunner creates a new performance test runner over one or more weighted
+// Scenarios. opts can attach sinks, pick the output format, or otherwise
+// tweak optional behavior; see WithSinks, WithOutputFormat, WithName,
+// WithWeightedRandomScenarios, and WithRetry.
+//
+// NewRunner panics if scenarios is empty - a Runner with nothing to call
+// would otherwise panic later, mid-run, the first time doTick picks a
+// scenario.
 func NewRunner(
 	duration time.Duration,
-	callbackFn CallbackFn,
+	scenarios []Scenario,
 	callsPerSecond uint16,
 	verbose bool,
+	opts ...RunnerOption,
 ) *Runner {
-	return &Runner{
-		callbackFn:     callbackFn,
+	if len(scenarios) == 0 {
+		panic("performance: NewRunner requires at least one Scenario")
+	}
+
+	r := &Runner{
+		scenarios:      scenarios,
 		duration:       duration,
 		callsPerSecond: callsPerSecond,
 		verbose:        verbose,
+		name:           "LoadTest",
+		clock:          realClock{},
+		global:         newRequestStats(runtime.GOMAXPROCS(0)),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
 
-		errors:   sync.Map{},
-		okCount:  counter.NewUnsigned(),
-		errCount: counter.NewUnsigned(),
+	if r.weightedRandomSeed != nil {
+		r.picker = newWeightedRandomPicker(scenarios, *r.weightedRandomSeed)
+	} else {
+		r.picker = newSmoothRoundRobin(scenarios)
 	}
+
+	r.scenarioStates = make(map[string]*requestStats, len(scenarios))
+
+	for _, scenario := range scenarios {
+		r.scenarioStates[scenario.Name] = newRequestStats(runtime.GOMAXPROCS(0))
+	}
+
+	return r
 }
 
 // Run starts running the performance suite and collecting metrics until the
@@ -59,14 +120,14 @@ func (p *Runner) Run(ctx context.Context, rampUp *time.Duration) (Summary, error
 		return *p.summary, nil
 	}
 
-	ticker := time.NewTicker(time.Second)
+	ticker := p.clock.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	timer := time.NewTimer(p.duration)
+	timer := p.clock.NewTimer(p.duration)
 	defer timer.Stop()
 
 	timeout := p.duration
-	rampUpTkr := time.NewTicker(time.Second)
+	rampUpTkr := p.clock.NewTicker(time.Second)
 
 	if rampUp == nil {
 		s := time.Second
@@ -79,7 +140,16 @@ func (p *Runner) Run(ctx context.Context, rampUp *time.Duration) (Summary, error
 	reqSec := delta
 
 	done := ctx.Done()
-	p.startedAt = time.Now()
+	p.startedAt = p.clock.Now()
+
+	p.startProfiling()
+	defer p.stopProfiling()
+
+	p.runtimeMetricsBase = sampleRuntimeMetrics(p.runtimeMetricNames)
+
+	if len(p.runtimeMetricNames) > 0 {
+		runtime.ReadMemStats(&p.memStatsBase)
+	}
 
 	fmt.Printf("\r[%s] ", timeout.String())
 	defer println()
@@ -92,15 +162,44 @@ func (p *Runner) Run(ctx context.Context, rampUp *time.Duration) (Summary, error
 			// stop runner and wait for goroutines to finish
 			ticker.Stop()
 			wg.Wait()
+			p.flushSinks(ctx)
+
+			summary := p.buildSummary()
+			p.printSummary(summary)
+
+			return summary, nil
+		case <-timer.C():
+			// the ticker may have become ready in this same instant (e.g. a
+			// duration that lands exactly on a tick boundary); dispatch it
+			// before stopping so the select race doesn't silently drop the
+			// final second of requests.
+			// A tick that arrives once the duration is already used up is
+			// not dispatched.
+			select {
+			case <-ticker.C():
+				if timeout > 0 {
+					p.doTick(&wg, reqSec)
+				}
+			default:
+			}
 
-			return p.buildSummary(), nil
-		case <-timer.C:
 			// stop runner and wait for goroutines to finish
 			ticker.Stop()
 			wg.Wait()
+			p.flushSinks(ctx)
+
+			summary := p.buildSummary()
+			p.printSummary(summary)
+
+			return summary, nil
+		case <-ticker.C():
+			if timeout <= 0 {
+				// the timer has already elapsed; let the timer branch
+				// handle the final drain instead of dispatching another
+				// tick here.
+				continue
+			}
 
-			return p.buildSummary(), nil
-		case <-ticker.C:
 			// time to send the requests for this second
 			p.doTick(&wg, reqSec)
 
@@ -112,7 +211,12 @@ func (p *Runner) Run(ctx context.Context, rampUp *time.Duration) (Summary, error
 			fmt.Printf("\r[%s] ", timeout.String())
 
 			wg.Wait()
-		case <-rampUpTkr.C:
+
+			// push whatever this tick observed to every sink now, rather
+			// than only once at the end, so a dashboard watching the sink
+			// sees the run progress live instead of a single dump at exit.
+			p.flushSinks(ctx)
+		case <-rampUpTkr.C():
 			if reqSec >= float64(p.callsPerSecond) {
 				rampUpTkr.Stop()
 
@@ -135,83 +239,174 @@ func (p *Runner) doTick(wg *sync.WaitGroup, reqSec float64) {
 
 			// sleep an amount of time so requests are sent (more or less)
 			// evenly within the same second.
-			time.Sleep(time.Duration(float64(time.Second) / reqSec * float64(seq)))
+			p.clock.Sleep(time.Duration(float64(time.Second) / reqSec * float64(seq)))
 
-			startAt := time.Now()
-			gErr := p.callbackFn()
-			duration := time.Since(startAt)
+			scenario := p.scenarios[p.picker.next()]
+			state := p.scenarioStates[scenario.Name]
 
-			defer p.latencies.Store(ulid.Make(), duration)
+			maxAttempts := p.retry.maxAttempts()
 
-			if gErr != nil {
-				p.debug("F")
-				p.errCount.Up()
+			var gErr error
+			var attempt uint8
+
+			for attempt = 1; attempt <= maxAttempts; attempt++ {
+				startAt := p.clock.Now()
+				gErr = scenario.Fn()
+				duration := p.clock.Now().Sub(startAt)
+
+				p.global.recordAttempt(int(seq), duration)
+				state.recordAttempt(int(seq), duration)
+				p.observeSinkLatency(duration)
+
+				if attempt == maxAttempts || !p.retry.retryable(gErr) {
+					break
+				}
 
-				loaded, _ := p.errors.LoadOrStore(gErr.Error(), counter.NewUnsigned())
-				loaded.(*counter.UnsignedCounter).Up()
+				p.clock.Sleep(p.retry.backoff(int(attempt)))
+			}
 
-				return
+			if gErr != nil {
+				p.debug("F")
+			} else {
+				p.debug(".")
 			}
 
-			p.debug(".")
-			p.okCount.Up()
+			p.global.recordVerdict(attempt, gErr)
+			state.recordVerdict(attempt, gErr)
+			p.observeSinkVerdict(gErr)
 		}(i)
 	}
 }
 
 func (p *Runner) buildSummary() Summary {
-	total := p.okCount.Get() + p.errCount.Get()
-	errPercent := (float64(p.errCount.Get()) * 100) / float64(total)
+	elapsed := p.clock.Now().Sub(p.startedAt)
+
+	report := buildSummaryFrom(p.global, elapsed)
+	if report.Total == 0 {
+		return report
+	}
+
+	if len(p.runtimeMetricNames) > 0 {
+		current := sampleRuntimeMetrics(p.runtimeMetricNames)
+		report.RuntimeMetrics = diffRuntimeMetrics(p.runtimeMetricsBase, current, defaultPercentiles)
+
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+
+		report.Allocs = memStats.Mallocs - p.memStatsBase.Mallocs
+		report.AllocBytes = memStats.TotalAlloc - p.memStatsBase.TotalAlloc
+	}
+
+	if len(p.scenarios) > 1 {
+		report.Scenarios = make(map[string]Summary, len(p.scenarios))
+
+		for _, scenario := range p.scenarios {
+			report.Scenarios[scenario.Name] = buildSummaryFrom(p.scenarioStates[scenario.Name], elapsed)
+		}
+	}
+
+	return report
+}
+
+// buildSummaryFrom computes a Summary from a single requestStats. It's
+// shared by the global roll-up and every per-scenario sub-summary, which
+// track the same bookkeeping independently.
+func buildSummaryFrom(stats *requestStats, elapsed time.Duration) Summary {
+	total := stats.okCount.Get() + stats.errCount.Get()
 
 	if total == 0 {
 		return Summary{}
 	}
 
+	errPercent := (float64(stats.errCount.Get()) * 100) / float64(total)
+
 	report := Summary{
 		Total:          total,
-		Time:           time.Since(p.startedAt),
-		Failed:         p.errCount.Get(),
+		Time:           elapsed,
+		Failed:         stats.errCount.Get(),
 		FailedPercent:  errPercent,
-		Success:        p.okCount.Get(),
-		SuccessPercent: (float64(p.okCount.Get()) * 100) / float64(total),
+		Success:        stats.okCount.Get(),
+		SuccessPercent: (float64(stats.okCount.Get()) * 100) / float64(total),
 		Errors:         map[string]uint64{},
-		Latencies:      map[uint8]int64{},
+		Retries:        stats.retries.Get(),
+		RetryHistogram: map[uint8]uint64{},
 	}
 
 	if errPercent > 0 {
-		p.errors.Range(func(key, value interface{}) bool {
+		stats.errors.Range(func(key, value interface{}) bool {
 			report.Errors[key.(string)] = value.(*counter.UnsignedCounter).Get()
 
 			return true
 		})
 	}
 
-	samples := make([]int64, 0)
-
-	p.latencies.Range(func(_, obs interface{}) bool {
-		samples = append(samples, obs.(time.Duration).Milliseconds())
+	stats.retryHistogram.Range(func(key, value interface{}) bool {
+		report.RetryHistogram[key.(uint8)] = value.(*counter.UnsignedCounter).Get()
 
 		return true
 	})
 
-	sort.Slice(samples, func(i, j int) bool {
-		return samples[i] < samples[j]
-	})
+	hstats, percentiles := stats.latencies.merge(defaultPercentiles)
 
-	// compute percentiles
-	percentiles := []uint8{50, 75, 90, 95, 99}
-	for _, percentile := range percentiles {
-		index := int(float64(len(samples)) * (float64(percentile) / 100))
-		if index == 0 {
-			index = 1
-		}
+	report.Latencies = LatencyStats{
+		Min:         time.Duration(hstats.Min),
+		Max:         time.Duration(hstats.Max),
+		Mean:        time.Duration(hstats.Mean),
+		StdDev:      time.Duration(hstats.StdDev),
+		Worst:       time.Duration(hstats.Max),
+		Percentiles: make(map[float64]time.Duration, len(percentiles)),
+	}
 
-		report.Latencies[percentile] = samples[index-1]
+	for percentile, v := range percentiles {
+		report.Latencies.Percentiles[percentile] = time.Duration(v)
 	}
 
 	return report
 }
 
+// observeSinkLatency feeds a single attempt's latency to every configured
+// Sink, independent of whether that attempt is the one that decides the
+// logical request's verdict.
+func (p *Runner) observeSinkLatency(duration time.Duration) {
+	for _, sink := range p.sinks {
+		sink.ObserveLatency(duration)
+	}
+}
+
+// observeSinkVerdict reports a logical request's final outcome - after all
+// retries - to every configured Sink.
+func (p *Runner) observeSinkVerdict(gErr error) {
+	for _, sink := range p.sinks {
+		if gErr != nil {
+			sink.IncFailure(gErr)
+
+			continue
+		}
+
+		sink.IncSuccess()
+	}
+}
+
+// flushSinks gives every configured Sink a chance to push buffered data. It
+// runs after every tick and once more before the runner exits.
+func (p *Runner) flushSinks(ctx context.Context) {
+	for _, sink := range p.sinks {
+		if err := sink.Flush(ctx); err != nil {
+			p.debug(fmt.Sprintf("sink flush error: %s", err))
+		}
+	}
+}
+
+// printSummary renders the final Summary in the configured OutputFormat.
+func (p *Runner) printSummary(s Summary) {
+	switch p.outputFormat {
+	case OutputFormatBenchmark:
+		fmt.Println(s.BenchmarkFormat(p.name))
+	default:
+		fmt.Println(s.String())
+	}
+}
+
 func (p *Runner) debug(msg string) {
 	if p.verbose {
 		print(msg)
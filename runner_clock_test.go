@@ -0,0 +1,142 @@
+package performance_test
+
+import (
+	"context"
+	"performance"
+	"performance/fakeclock"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_RampUp(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+
+	var calls int64
+
+	fn := func() error {
+		atomic.AddInt64(&calls, 1)
+
+		return nil
+	}
+
+	rampUp := 5 * time.Second
+	callsPerSecond := uint16(10)
+
+	scenarios := []performance.Scenario{{Name: "default", Weight: 1, Fn: fn}}
+
+	runner := performance.NewRunner(10*time.Second, scenarios, callsPerSecond, false, performance.WithClock(clock))
+
+	summaryCh := make(chan performance.Summary, 1)
+
+	go func() {
+		summary, err := runner.Run(context.Background(), &rampUp)
+		require.NoError(t, err)
+		summaryCh <- summary
+	}()
+
+	// Run registers its ticker/timer/rampUp ticker with the clock at the
+	// very start of Run, asynchronously with this goroutine; wait for that
+	// registration before advancing, or Advance would race ahead and the
+	// clock's fire times would be computed against an already-advanced now.
+	clock.WaitForWatchers(3)
+
+	// step through the ramp-up window one second at a time; the request
+	// rate should grow, not jump straight to callsPerSecond.
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Second)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	midRun := atomic.LoadInt64(&calls)
+	require.Greater(t, midRun, int64(0))
+	require.Less(t, midRun, int64(callsPerSecond)*5)
+
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Second)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// the tick dispatched exactly at the duration boundary spaces its
+	// requests up to just under a second past it; advance once more so
+	// that in-flight work can actually drain before Run returns.
+	clock.Advance(time.Second)
+
+	select {
+	case summary := <-summaryCh:
+		require.Greater(t, summary.Total, uint64(midRun))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the duration and final drain advance")
+	}
+}
+
+func TestRunner_TimerExpiryDrainsInFlight(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+
+	callsPerSecond := uint16(4)
+
+	scenarios := []performance.Scenario{{Name: "default", Weight: 1, Fn: func() error {
+		return nil
+	}}}
+
+	runner := performance.NewRunner(3*time.Second, scenarios, callsPerSecond, false, performance.WithClock(clock))
+
+	summaryCh := make(chan performance.Summary, 1)
+
+	go func() {
+		summary, err := runner.Run(context.Background(), nil)
+		require.NoError(t, err)
+		summaryCh <- summary
+	}()
+
+	clock.WaitForWatchers(3)
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// the tick dispatched exactly at the duration boundary spaces its
+	// requests up to just under a second past it; advance once more so
+	// that in-flight work can actually drain before Run returns.
+	clock.Advance(time.Second)
+
+	select {
+	case summary := <-summaryCh:
+		require.EqualValues(t, 3*callsPerSecond, summary.Total)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the duration and final drain advance")
+	}
+}
+
+func TestRunner_ContextCancellationShortCircuits(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+
+	scenarios := []performance.Scenario{{Name: "default", Weight: 1, Fn: func() error {
+		return nil
+	}}}
+
+	runner := performance.NewRunner(time.Hour, scenarios, 1, false, performance.WithClock(clock))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	summaryCh := make(chan performance.Summary, 1)
+	errCh := make(chan error, 1)
+
+	go func() {
+		summary, err := runner.Run(ctx, nil)
+		summaryCh <- summary
+		errCh <- err
+	}()
+
+	cancel()
+
+	select {
+	case <-summaryCh:
+		require.NoError(t, <-errCh)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not short-circuit on context cancellation")
+	}
+}
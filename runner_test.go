@@ -31,7 +31,9 @@ func TestRunner(t *testing.T) {
 	seconds := 6
 	callsPerSecond := 10
 
-	runner := performance.NewRunner(time.Duration(seconds)*time.Second, fn, uint16(callsPerSecond), false)
+	scenarios := []performance.Scenario{{Name: "default", Weight: 1, Fn: fn}}
+
+	runner := performance.NewRunner(time.Duration(seconds)*time.Second, scenarios, uint16(callsPerSecond), false)
 	summary, err := runner.Run(ctx, nil)
 
 	require.NoError(t, err)
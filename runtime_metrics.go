@@ -0,0 +1,152 @@
+package performance
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+// RuntimeMetricStats summarizes the distribution of a single
+// histogram-valued runtime/metrics sample (e.g. "/gc/pauses:seconds" or
+// "/sched/latencies:seconds") observed over the course of a run.
+type RuntimeMetricStats struct {
+	// Min and Max are the narrowest and widest observed bucket values.
+	Min time.Duration
+	Max time.Duration
+
+	// Mean is the weighted average of all observed bucket values.
+	Mean time.Duration
+
+	// Percentiles maps a requested percentile (e.g. 50, 99, 99.9) to its
+	// observed value.
+	Percentiles map[float64]time.Duration
+}
+
+// sampleRuntimeMetrics reads the current value of every metric name in
+// names, keeping only the histogram-valued ones - which is what the GC
+// pause and scheduling latency metrics this feature targets are.
+func sampleRuntimeMetrics(names []string) map[string]*metrics.Float64Histogram {
+	if len(names) == 0 {
+		return nil
+	}
+
+	samples := make([]metrics.Sample, len(names))
+	for i, name := range names {
+		samples[i].Name = name
+	}
+
+	metrics.Read(samples)
+
+	out := make(map[string]*metrics.Float64Histogram, len(samples))
+
+	for _, sample := range samples {
+		if sample.Value.Kind() == metrics.KindFloat64Histogram {
+			out[sample.Name] = sample.Value.Float64Histogram()
+		}
+	}
+
+	return out
+}
+
+// diffRuntimeMetrics computes, for every metric present in end, the
+// distribution of values observed between start and end. runtime/metrics
+// histograms are cumulative since process start, so a single start/end diff
+// captures everything observed during the run without needing to poll
+// mid-run.
+func diffRuntimeMetrics(
+	start, end map[string]*metrics.Float64Histogram, percentiles []float64,
+) map[string]RuntimeMetricStats {
+	out := make(map[string]RuntimeMetricStats, len(end))
+
+	for name, endHist := range end {
+		out[name] = diffHistogram(start[name], endHist, percentiles)
+	}
+
+	return out
+}
+
+func diffHistogram(start, end *metrics.Float64Histogram, percentiles []float64) RuntimeMetricStats {
+	stats := RuntimeMetricStats{Percentiles: make(map[float64]time.Duration, len(percentiles))}
+
+	if end == nil {
+		return stats
+	}
+
+	counts := make([]uint64, len(end.Counts))
+
+	var total uint64
+
+	for i := range counts {
+		var before uint64
+		if start != nil && i < len(start.Counts) {
+			before = start.Counts[i]
+		}
+
+		if end.Counts[i] > before {
+			counts[i] = end.Counts[i] - before
+		}
+
+		total += counts[i]
+	}
+
+	if total == 0 {
+		return stats
+	}
+
+	targets := make(map[float64]uint64, len(percentiles))
+
+	for _, p := range percentiles {
+		target := uint64(math.Ceil(float64(total) * p / 100))
+		if target == 0 {
+			target = 1
+		}
+
+		targets[p] = target
+	}
+
+	var sum float64
+
+	var seen, cum uint64
+
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+
+		value := bucketValue(end.Buckets, i)
+		duration := time.Duration(value * float64(time.Second))
+
+		if seen == 0 {
+			stats.Min = duration
+		}
+
+		stats.Max = duration
+		sum += value * float64(n)
+
+		seen += n
+		cum += n
+
+		for p, target := range targets {
+			if _, ok := stats.Percentiles[p]; !ok && cum >= target {
+				stats.Percentiles[p] = duration
+			}
+		}
+	}
+
+	stats.Mean = time.Duration(sum / float64(total) * float64(time.Second))
+
+	return stats
+}
+
+// bucketValue returns a representative value (in seconds) for histogram
+// bucket i, using the lower bound when the upper bound is unbounded (as is
+// the case for the last bucket of most runtime/metrics histograms).
+func bucketValue(bounds []float64, i int) float64 {
+	lo, hi := bounds[i], bounds[i+1]
+
+	if math.IsInf(hi, 1) {
+		return lo
+	}
+
+	return (lo + hi) / 2
+}
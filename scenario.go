@@ -0,0 +1,108 @@
+package performance
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Scenario is a named, weighted unit of work. A Runner configured with
+// several scenarios mixes them on each tick proportionally to their
+// Weight, modeling the realistic case of a load test hitting several
+// endpoints in a fixed traffic ratio (e.g. 70% reads, 25% writes, 5%
+// deletes) while still reporting per-endpoint stats.
+type Scenario struct {
+	Name   string
+	Weight int
+	Fn     CallbackFn
+}
+
+// scenarioPicker selects which scenario index to run next.
+type scenarioPicker interface {
+	next() int
+}
+
+// scenarioWeights normalizes a scenario list's weights, treating anything
+// less than 1 as 1.
+func scenarioWeights(scenarios []Scenario) (weights []int, total int) {
+	weights = make([]int, len(scenarios))
+
+	for i, s := range scenarios {
+		w := s.Weight
+		if w < 1 {
+			w = 1
+		}
+
+		weights[i] = w
+		total += w
+	}
+
+	return weights, total
+}
+
+// smoothRoundRobin is the default scenarioPicker: a deterministic,
+// interleaved round-robin proportional to each scenario's weight (the same
+// algorithm nginx uses to balance weighted upstreams), so a 70/25/5 mix
+// doesn't burst 70 calls to the first scenario before touching the others.
+type smoothRoundRobin struct {
+	mu      sync.Mutex
+	weights []int
+	current []int
+	total   int
+}
+
+func newSmoothRoundRobin(scenarios []Scenario) *smoothRoundRobin {
+	weights, total := scenarioWeights(scenarios)
+
+	return &smoothRoundRobin{weights: weights, current: make([]int, len(scenarios)), total: total}
+}
+
+func (r *smoothRoundRobin) next() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	best := 0
+
+	for i, w := range r.weights {
+		r.current[i] += w
+
+		if r.current[i] > r.current[best] {
+			best = i
+		}
+	}
+
+	r.current[best] -= r.total
+
+	return best
+}
+
+// weightedRandomPicker selects a scenario at random, weighted by Weight,
+// using a seeded RNG so a run can be reproduced.
+type weightedRandomPicker struct {
+	mu      sync.Mutex
+	rng     *rand.Rand
+	weights []int
+	total   int
+}
+
+func newWeightedRandomPicker(scenarios []Scenario, seed int64) *weightedRandomPicker {
+	weights, total := scenarioWeights(scenarios)
+
+	return &weightedRandomPicker{rng: rand.New(rand.NewSource(seed)), weights: weights, total: total}
+}
+
+func (r *weightedRandomPicker) next() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.rng.Intn(r.total)
+
+	for i, w := range r.weights {
+		if n < w {
+			return i
+		}
+
+		n -= w
+	}
+
+	return len(r.weights) - 1
+}
@@ -0,0 +1,25 @@
+package performance
+
+import (
+	"context"
+	"time"
+)
+
+// Sink receives live metrics as a Runner executes, so a run can be watched
+// in real time (e.g. from a Grafana dashboard) instead of only producing a
+// Summary once it finishes.
+type Sink interface {
+	// ObserveLatency records the latency of a single call.
+	ObserveLatency(time.Duration)
+
+	// IncSuccess records one successful call.
+	IncSuccess()
+
+	// IncFailure records one failed call, labelled by the error it
+	// returned.
+	IncFailure(err error)
+
+	// Flush gives the sink a chance to push any buffered data. The runner
+	// calls it after every tick and once more before it exits.
+	Flush(ctx context.Context) error
+}
@@ -0,0 +1,90 @@
+package performance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// InfluxDBSink batches observations as InfluxDB line protocol and posts
+// them to a /write endpoint on Flush.
+type InfluxDBSink struct {
+	writeURL    string
+	measurement string
+	client      *http.Client
+
+	mu     sync.Mutex
+	points []string
+}
+
+// NewInfluxDBSink returns a sink that posts to writeURL (e.g.
+// "http://localhost:8086/write?db=loadtest") using the given measurement
+// name.
+func NewInfluxDBSink(writeURL, measurement string) *InfluxDBSink {
+	return &InfluxDBSink{
+		writeURL:    writeURL,
+		measurement: measurement,
+		client:      http.DefaultClient,
+	}
+}
+
+// ObserveLatency implements Sink.
+func (s *InfluxDBSink) ObserveLatency(d time.Duration) {
+	s.appendPoint(fmt.Sprintf("%s latency_ms=%d", s.measurement, d.Milliseconds()))
+}
+
+// IncSuccess implements Sink.
+func (s *InfluxDBSink) IncSuccess() {
+	s.appendPoint(fmt.Sprintf("%s success=1i", s.measurement))
+}
+
+// IncFailure implements Sink.
+func (s *InfluxDBSink) IncFailure(err error) {
+	s.appendPoint(fmt.Sprintf("%s failure=1i,error=%q", s.measurement, err.Error()))
+}
+
+func (s *InfluxDBSink) appendPoint(line string) {
+	s.mu.Lock()
+	s.points = append(s.points, line)
+	s.mu.Unlock()
+}
+
+// Flush posts all points batched since the last Flush and clears the
+// buffer.
+func (s *InfluxDBSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	points := s.points
+	s.points = nil
+	s.mu.Unlock()
+
+	if len(points) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+
+	for _, p := range points {
+		body.WriteString(p)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.writeURL, &body)
+	if err != nil {
+		return fmt.Errorf("building influxdb write request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to influxdb: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("influxdb write failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package performance
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PrometheusSinkConfig configures a PrometheusSink.
+type PrometheusSinkConfig struct {
+	// Namespace and Subsystem are forwarded to every metric's fully
+	// qualified name.
+	Namespace string
+	Subsystem string
+
+	// Buckets overrides the default latency histogram buckets, in seconds.
+	Buckets []float64
+
+	// Registerer defaults to prometheus.DefaultRegisterer.
+	Registerer prometheus.Registerer
+}
+
+// PrometheusSink exposes live load-test metrics as Prometheus collectors via
+// an embedded http.Handler, so results can be scraped while the run is still
+// in progress.
+type PrometheusSink struct {
+	latency  prometheus.Histogram
+	success  prometheus.Counter
+	failures *prometheus.CounterVec
+
+	handler http.Handler
+}
+
+// NewPrometheusSink creates a PrometheusSink and registers its collectors
+// against cfg.Registerer.
+func NewPrometheusSink(cfg PrometheusSinkConfig) *PrometheusSink {
+	if cfg.Buckets == nil {
+		cfg.Buckets = prometheus.DefBuckets
+	}
+
+	if cfg.Registerer == nil {
+		cfg.Registerer = prometheus.DefaultRegisterer
+	}
+
+	sink := &PrometheusSink{
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: cfg.Subsystem,
+			Name:      "latency_seconds",
+			Help:      "Latency of callback executions, in seconds.",
+			Buckets:   cfg.Buckets,
+		}),
+		success: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: cfg.Subsystem,
+			Name:      "success_total",
+			Help:      "Number of successful callback executions.",
+		}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: cfg.Subsystem,
+			Name:      "failure_total",
+			Help:      "Number of failed callback executions, labelled by error.",
+		}, []string{"error"}),
+	}
+
+	cfg.Registerer.MustRegister(sink.latency, sink.success, sink.failures)
+
+	// Serve from the same registry the collectors were registered against;
+	// promhttp.Handler() always gathers prometheus.DefaultGatherer, which
+	// would serve nothing for a caller-supplied Registerer.
+	gatherer, ok := cfg.Registerer.(prometheus.Gatherer)
+	if !ok {
+		gatherer = prometheus.DefaultGatherer
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	sink.handler = mux
+
+	return sink
+}
+
+// ObserveLatency implements Sink.
+func (s *PrometheusSink) ObserveLatency(d time.Duration) {
+	s.latency.Observe(d.Seconds())
+}
+
+// IncSuccess implements Sink.
+func (s *PrometheusSink) IncSuccess() {
+	s.success.Inc()
+}
+
+// IncFailure implements Sink.
+func (s *PrometheusSink) IncFailure(err error) {
+	s.failures.WithLabelValues(err.Error()).Inc()
+}
+
+// Flush implements Sink. Prometheus is pull-based, so there is nothing to
+// push.
+func (s *PrometheusSink) Flush(_ context.Context) error {
+	return nil
+}
+
+// Handler returns the http.Handler serving this sink's /metrics endpoint.
+func (s *PrometheusSink) Handler() http.Handler {
+	return s.handler
+}
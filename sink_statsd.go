@@ -0,0 +1,56 @@
+package performance
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// StatsDSink emits metrics as StatsD/DogStatsD lines over UDP.
+type StatsDSink struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsDSink dials addr (host:port) and returns a sink that writes to it.
+// Metric names are prefixed with prefix plus a trailing dot, unless prefix
+// is empty.
+func NewStatsDSink(addr, prefix string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dialing statsd at %q: %w", addr, err)
+	}
+
+	if prefix != "" {
+		prefix += "."
+	}
+
+	return &StatsDSink{conn: conn, prefix: prefix}, nil
+}
+
+// ObserveLatency implements Sink, emitting a histogram metric.
+func (s *StatsDSink) ObserveLatency(d time.Duration) {
+	s.write(fmt.Sprintf("%slatency_ms:%d|h", s.prefix, d.Milliseconds()))
+}
+
+// IncSuccess implements Sink.
+func (s *StatsDSink) IncSuccess() {
+	s.write(fmt.Sprintf("%ssuccess:1|c", s.prefix))
+}
+
+// IncFailure implements Sink, tagging the counter with the error message
+// (DogStatsD tag syntax).
+func (s *StatsDSink) IncFailure(err error) {
+	s.write(fmt.Sprintf("%sfailure:1|c|#error:%s", s.prefix, err.Error()))
+}
+
+// Flush implements Sink. UDP writes are fire-and-forget, so there is
+// nothing buffered to push.
+func (s *StatsDSink) Flush(_ context.Context) error {
+	return nil
+}
+
+func (s *StatsDSink) write(line string) {
+	_, _ = s.conn.Write([]byte(line))
+}
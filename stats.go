@@ -0,0 +1,57 @@
+package performance
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Avalanche-io/counter"
+)
+
+// requestStats is the bookkeeping needed to build a Summary. The Runner
+// keeps one globally plus one per Scenario, so each gets an independent
+// report built the same way.
+type requestStats struct {
+	errors         sync.Map
+	latencies      *histogram
+	okCount        *counter.UnsignedCounter
+	errCount       *counter.UnsignedCounter
+	retries        *counter.UnsignedCounter
+	retryHistogram sync.Map
+}
+
+func newRequestStats(shardCount int) *requestStats {
+	return &requestStats{
+		latencies: newHistogram(histogramLowest, histogramHighest, histogramSignificantFigures, shardCount),
+		okCount:   counter.NewUnsigned(),
+		errCount:  counter.NewUnsigned(),
+		retries:   counter.NewUnsigned(),
+	}
+}
+
+// recordAttempt records a single attempt's latency, independent of whether
+// it ultimately succeeds or gets retried.
+func (s *requestStats) recordAttempt(shardHint int, duration time.Duration) {
+	s.latencies.record(shardHint, duration)
+}
+
+// recordVerdict records the final outcome of a logical request - after all
+// retries - plus how many attempts it took.
+func (s *requestStats) recordVerdict(attempts uint8, gErr error) {
+	loaded, _ := s.retryHistogram.LoadOrStore(attempts, counter.NewUnsigned())
+	loaded.(*counter.UnsignedCounter).Up()
+
+	for i := uint8(1); i < attempts; i++ {
+		s.retries.Up()
+	}
+
+	if gErr != nil {
+		s.errCount.Up()
+
+		errLoaded, _ := s.errors.LoadOrStore(gErr.Error(), counter.NewUnsigned())
+		errLoaded.(*counter.UnsignedCounter).Up()
+
+		return
+	}
+
+	s.okCount.Up()
+}
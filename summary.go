@@ -3,6 +3,7 @@ package performance
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,9 +34,58 @@ type Summary struct {
 	// during the test.
 	Errors map[string]uint64
 
-	// Latencies the list of observed latencies (in Milliseconds) indexed by
-	// percentile.
-	Latencies map[uint8]int64
+	// Latencies summarizes the full observed latency distribution, across
+	// every attempt of every logical request.
+	Latencies LatencyStats
+
+	// Retries is the number of retry attempts performed, i.e. the number of
+	// attempts beyond each logical request's first. Zero unless the Runner
+	// was configured with WithRetry.
+	Retries uint64
+
+	// RetryHistogram maps a logical request's total attempt count to the
+	// number of requests that took exactly that many attempts. A request
+	// that succeeded on the first try counts under key 1.
+	RetryHistogram map[uint8]uint64
+
+	// RuntimeMetrics summarizes any runtime/metrics histograms requested
+	// via WithRuntimeMetrics, keyed by metric name (e.g.
+	// "/gc/pauses:seconds"). Empty unless WithRuntimeMetrics was used.
+	RuntimeMetrics map[string]RuntimeMetricStats
+
+	// Allocs and AllocBytes report the number of heap allocations and bytes
+	// allocated over the course of the run. Both are zero unless
+	// WithRuntimeMetrics was used.
+	Allocs     uint64
+	AllocBytes uint64
+
+	// Scenarios holds a per-scenario sub-summary, keyed by Scenario.Name.
+	// Empty unless the Runner was configured with more than one Scenario.
+	Scenarios map[string]Summary
+}
+
+// LatencyStats summarizes the distribution of observed latencies, as
+// reported by the underlying HDR histogram.
+type LatencyStats struct {
+	// Min is the fastest observed call.
+	Min time.Duration
+
+	// Max is the slowest observed call. Equal to Worst.
+	Max time.Duration
+
+	// Mean is the arithmetic mean of all observed calls.
+	Mean time.Duration
+
+	// StdDev is the standard deviation of all observed calls.
+	StdDev time.Duration
+
+	// Worst is an alias of Max, surfaced separately since it's what users
+	// hunting GC pauses and other tail latencies look at first.
+	Worst time.Duration
+
+	// Percentiles maps a requested percentile (e.g. 50, 99, 99.9, 99.99) to
+	// the observed latency at that percentile.
+	Percentiles map[float64]time.Duration
 }
 
 func (s Summary) String() string {
@@ -47,17 +97,36 @@ func (s Summary) String() string {
 		fmt.Sprintf("- Success ✔: %d (%.2f%%)", s.Success, s.SuccessPercent),
 		fmt.Sprintf("- Failures ✘: %d (%.2f%%)", s.Failed, s.FailedPercent),
 		"- Latencies:",
+		fmt.Sprintf("  - min = %s", s.Latencies.Min),
+		fmt.Sprintf("  - mean = %s", s.Latencies.Mean),
+		fmt.Sprintf("  - stddev = %s", s.Latencies.StdDev),
 	)
 
-	latencies := make([]string, 0, len(s.Latencies))
+	percentiles := make([]string, 0, len(s.Latencies.Percentiles))
 
-	for p, obs := range s.Latencies {
-		latencies = append(latencies, fmt.Sprintf("  - p(%d) = %d ms", p, obs))
+	for p, obs := range s.Latencies.Percentiles {
+		percentiles = append(percentiles, fmt.Sprintf("  - p(%s) = %s", formatPercentile(p), obs))
 	}
 
-	sort.Strings(latencies)
+	sort.Strings(percentiles)
 
-	lines = append(lines, latencies...)
+	lines = append(lines, percentiles...)
+	lines = append(lines, fmt.Sprintf("  - worst = %s", s.Latencies.Worst))
+
+	if s.Retries > 0 {
+		lines = append(lines, fmt.Sprintf("- Retries: %d", s.Retries))
+
+		attempts := make([]uint8, 0, len(s.RetryHistogram))
+		for a := range s.RetryHistogram {
+			attempts = append(attempts, a)
+		}
+
+		sort.Slice(attempts, func(i, j int) bool { return attempts[i] < attempts[j] })
+
+		for _, a := range attempts {
+			lines = append(lines, fmt.Sprintf("  - %d attempt(s): %d", a, s.RetryHistogram[a]))
+		}
+	}
 
 	if s.FailedPercent > 0 {
 		lines = append(lines, "- Errors:")
@@ -72,7 +141,102 @@ func (s Summary) String() string {
 		lines = append(lines, errs...)
 	}
 
+	if len(s.Scenarios) > 0 {
+		lines = append(lines, "- Scenarios:")
+
+		names := make([]string, 0, len(s.Scenarios))
+		for name := range s.Scenarios {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			sc := s.Scenarios[name]
+
+			lines = append(lines,
+				fmt.Sprintf("  - %s:", name),
+				fmt.Sprintf(
+					"    - iterations = %d, success = %.2f%%, failed = %.2f%%", sc.Total, sc.SuccessPercent, sc.FailedPercent,
+				),
+				fmt.Sprintf("    - latencies: min = %s, mean = %s, max = %s", sc.Latencies.Min, sc.Latencies.Mean, sc.Latencies.Max),
+			)
+		}
+	}
+
+	if len(s.RuntimeMetrics) > 0 {
+		lines = append(lines, "- Runtime Metrics:")
+
+		names := make([]string, 0, len(s.RuntimeMetrics))
+		for name := range s.RuntimeMetrics {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			stats := s.RuntimeMetrics[name]
+
+			lines = append(lines,
+				fmt.Sprintf("  - %s:", name),
+				fmt.Sprintf("    - min = %s, mean = %s, max = %s", stats.Min, stats.Mean, stats.Max),
+			)
+
+			percentiles := make([]string, 0, len(stats.Percentiles))
+			for p, obs := range stats.Percentiles {
+				percentiles = append(percentiles, fmt.Sprintf("    - p(%s) = %s", formatPercentile(p), obs))
+			}
+
+			sort.Strings(percentiles)
+
+			lines = append(lines, percentiles...)
+		}
+	}
+
 	infoBox := box.New(box.Config{Px: 2, Py: 2, Type: "Double", TitlePos: "Top", Color: "Green"})
 
 	return infoBox.String("RESULTS", strings.Join(lines, "\n"))
 }
+
+// formatPercentile renders a percentile such as 99 or 99.9 without a
+// trailing ".0" for whole numbers.
+func formatPercentile(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// BenchmarkFormat renders the summary in the standard `go test -bench` text
+// format, one line per statistic, so multiple runs can be piped through
+// benchstat for A/B comparisons.
+func (s Summary) BenchmarkFormat(name string) string {
+	lines := make([]string, 0, len(s.Latencies.Percentiles)+2)
+
+	lines = append(lines, fmt.Sprintf("Benchmark%s/mean 1 %d ns/op", name, s.Latencies.Mean.Nanoseconds()))
+
+	percentiles := make([]float64, 0, len(s.Latencies.Percentiles))
+	for p := range s.Latencies.Percentiles {
+		percentiles = append(percentiles, p)
+	}
+
+	sort.Float64s(percentiles)
+
+	for _, p := range percentiles {
+		lines = append(lines, fmt.Sprintf(
+			"Benchmark%s/p%s 1 %d ns/op", name, formatPercentile(p), s.Latencies.Percentiles[p].Nanoseconds(),
+		))
+	}
+
+	lines = append(lines, fmt.Sprintf("Benchmark%s/worst 1 %d ns/op", name, s.Latencies.Worst.Nanoseconds()))
+
+	throughput := float64(s.Total) / s.Time.Seconds()
+	lines = append(lines, fmt.Sprintf("Benchmark%s/throughput 1 %.2f ops/sec", name, throughput))
+
+	if s.Retries > 0 {
+		lines = append(lines, fmt.Sprintf("Benchmark%s/retries 1 %d retries", name, s.Retries))
+	}
+
+	if len(s.RuntimeMetrics) > 0 {
+		lines = append(lines, fmt.Sprintf("# gc: %d allocs, %d B", s.Allocs, s.AllocBytes))
+	}
+
+	return strings.Join(lines, "\n")
+}